@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	yamlutil "sigs.k8s.io/yaml"
+)
+
+// ApplyOptions controls the labels stamped onto every object applied via applyYAMLToCluster,
+// so migration-created Providers/Plans/Migrations can be found and cleaned up as a set.
+type ApplyOptions struct {
+	Labels map[string]string
+}
+
+// applyYAMLToCluster decodes a (possibly multi-document) YAML stream into unstructured objects
+// and creates or updates each one against the cluster via the dynamic client. Each object's GVK
+// is resolved to a resource through mapper, so this works for any CRD (Provider, Plan, Migration,
+// ...) without a typed client. Apply is idempotent: an object that already exists is updated in
+// place on its current resourceVersion instead of returning AlreadyExists.
+func applyYAMLToCluster(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, yamlContent []byte, opts ApplyOptions) ([]*unstructured.Unstructured, error) {
+	var applied []*unstructured.Unstructured
+	for _, doc := range splitYAMLDocuments(yamlContent) {
+		if len(strings.TrimSpace(doc)) == 0 {
+			continue
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := yamlutil.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			return applied, fmt.Errorf("decoding YAML document: %w", err)
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return applied, fmt.Errorf("resolving REST mapping for %s: %w", gvk, err)
+		}
+
+		if len(opts.Labels) > 0 {
+			labels := obj.GetLabels()
+			if labels == nil {
+				labels = map[string]string{}
+			}
+			for k, v := range opts.Labels {
+				labels[k] = v
+			}
+			obj.SetLabels(labels)
+		}
+
+		var resource dynamic.ResourceInterface
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			resource = dynamicClient.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		} else {
+			resource = dynamicClient.Resource(mapping.Resource)
+		}
+
+		result, err := resource.Create(ctx, obj, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			existing, getErr := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if getErr != nil {
+				return applied, fmt.Errorf("fetching existing %s %q: %w", gvk.Kind, obj.GetName(), getErr)
+			}
+			obj.SetResourceVersion(existing.GetResourceVersion())
+			result, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return applied, fmt.Errorf("applying %s %q: %w", gvk.Kind, obj.GetName(), err)
+		}
+		applied = append(applied, result)
+	}
+	return applied, nil
+}
+
+// splitYAMLDocuments splits a multi-document YAML stream on "---" separators, the same approach
+// Podman's PlayKube flow uses to apply a stream of Kubernetes manifests one at a time.
+func splitYAMLDocuments(yamlContent []byte) []string {
+	return strings.Split(string(yamlContent), "\n---")
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// VMRecord describes a VM discovered in vCenter, with enough detail for the CLI/UI to prompt
+// the user for network/storage mappings instead of assuming hardcoded defaults.
+type VMRecord struct {
+	ID         string // MoRef ID, e.g. "vm-12345"
+	Name       string
+	PowerState string
+	GuestOS    string
+	DiskGB     []int64
+	Networks   []string
+	Datastores []string
+}
+
+// VMwareInventory discovers VMs in a vCenter Datacenter via govmomi. VMwareDetails.Cluster is
+// not used: lookups cover the whole Datacenter.
+type VMwareInventory struct {
+	client *govmomi.Client
+	finder *find.Finder
+}
+
+// NewVMwareInventory logs into vCenter at details.Host and scopes subsequent lookups to
+// details.Datacenter.
+func NewVMwareInventory(ctx context.Context, details VMwareDetails) (*VMwareInventory, error) {
+	u, err := url.Parse(fmt.Sprintf("https://%s/sdk", details.Host))
+	if err != nil {
+		return nil, fmt.Errorf("parsing vCenter URL: %w", err)
+	}
+	u.User = url.UserPassword(details.Username, details.Password)
+
+	client, err := govmomi.NewClient(ctx, u, true)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to vCenter %s: %w", details.Host, err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	dc, err := finder.DatacenterOrDefault(ctx, details.Datacenter)
+	if err != nil {
+		return nil, fmt.Errorf("resolving datacenter %q: %w", details.Datacenter, err)
+	}
+	finder.SetDatacenter(dc)
+
+	return &VMwareInventory{client: client, finder: finder}, nil
+}
+
+// Close logs out of vCenter.
+func (inv *VMwareInventory) Close(ctx context.Context) error {
+	return inv.client.Logout(ctx)
+}
+
+// ListVMs lists every VM visible under the configured Datacenter.
+func (inv *VMwareInventory) ListVMs(ctx context.Context) ([]VMRecord, error) {
+	vms, err := inv.finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		return nil, fmt.Errorf("listing VMs: %w", err)
+	}
+
+	records := make([]VMRecord, 0, len(vms))
+	for _, vm := range vms {
+		record, err := describeVM(ctx, vm)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// ResolveVMNames resolves each name in names to its VMRecord, preserving order, so
+// VMwareDetails.VMNames can be turned into concrete MoRef IDs before building a Plan.
+func (inv *VMwareInventory) ResolveVMNames(ctx context.Context, names []string) ([]VMRecord, error) {
+	all, err := inv.ListVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]VMRecord, len(all))
+	for _, record := range all {
+		byName[record.Name] = record
+	}
+
+	resolved := make([]VMRecord, 0, len(names))
+	for _, name := range names {
+		record, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("VM %q not found in vCenter inventory", name)
+		}
+		resolved = append(resolved, record)
+	}
+	return resolved, nil
+}
+
+// describeVM fetches the summary and hardware config for vm and converts it to a VMRecord.
+func describeVM(ctx context.Context, vm *object.VirtualMachine) (VMRecord, error) {
+	var moVM mo.VirtualMachine
+	if err := vm.Properties(ctx, vm.Reference(), []string{"summary", "config"}, &moVM); err != nil {
+		return VMRecord{}, fmt.Errorf("fetching properties for %s: %w", vm.Name(), err)
+	}
+
+	record := VMRecord{
+		ID:         vm.Reference().Value,
+		Name:       moVM.Summary.Config.Name,
+		PowerState: string(moVM.Summary.Runtime.PowerState),
+		GuestOS:    moVM.Summary.Config.GuestFullName,
+	}
+
+	if moVM.Config == nil {
+		return record, nil
+	}
+
+	seenDatastores := map[string]bool{}
+	for _, device := range moVM.Config.Hardware.Device {
+		switch d := device.(type) {
+		case *types.VirtualDisk:
+			record.DiskGB = append(record.DiskGB, d.CapacityInKB/(1024*1024))
+			if backing, ok := d.Backing.(types.BaseVirtualDeviceFileBackingInfo); ok {
+				ref := backing.GetVirtualDeviceFileBackingInfo().Datastore
+				if ref != nil {
+					name, err := resolveDatastoreName(ctx, vm.Client(), *ref)
+					if err == nil && !seenDatastores[name] {
+						seenDatastores[name] = true
+						record.Datastores = append(record.Datastores, name)
+					}
+				}
+			}
+		case types.BaseVirtualEthernetCard:
+			card := d.GetVirtualEthernetCard()
+			if name, err := resolveNetworkBackingName(ctx, vm.Client(), card.Backing); err == nil && name != "" {
+				record.Networks = append(record.Networks, name)
+			}
+		}
+	}
+	return record, nil
+}
+
+// resolveDatastoreName fetches the display name of the datastore referenced by ref.
+func resolveDatastoreName(ctx context.Context, client *vim25.Client, ref types.ManagedObjectReference) (string, error) {
+	var ds mo.Datastore
+	if err := property.DefaultCollector(client).RetrieveOne(ctx, ref, []string{"name"}, &ds); err != nil {
+		return "", err
+	}
+	return ds.Name, nil
+}
+
+// resolveNetworkBackingName identifies the portgroup/network backing a vNIC, rather than the
+// device's human-readable summary, so it matches what the NetworkMap reconciler in maps.go
+// pairs against destination NetworkAttachmentDefinitions.
+func resolveNetworkBackingName(ctx context.Context, client *vim25.Client, backing types.BaseVirtualDeviceBackingInfo) (string, error) {
+	switch b := backing.(type) {
+	case *types.VirtualEthernetCardNetworkBackingInfo:
+		return b.DeviceName, nil
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		if b.Port.PortgroupKey == "" {
+			return "", fmt.Errorf("distributed virtual port backing has no portgroup key")
+		}
+		ref := types.ManagedObjectReference{Type: "DistributedVirtualPortgroup", Value: b.Port.PortgroupKey}
+		var pg mo.DistributedVirtualPortgroup
+		if err := property.DefaultCollector(client).RetrieveOne(ctx, ref, []string{"name"}, &pg); err != nil {
+			return "", fmt.Errorf("resolving distributed portgroup %s: %w", b.Port.PortgroupKey, err)
+		}
+		return pg.Name, nil
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkId, nil
+	default:
+		return "", fmt.Errorf("unsupported network backing type %T", backing)
+	}
+}
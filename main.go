@@ -12,10 +12,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
-	"sigs.k8s.io/controller-runtime/pkg/client"
-	yamlutil "sigs.k8s.io/yaml"
 )
 
 // VMwareDetails captures vCenter info from UI
@@ -25,30 +28,91 @@ type VMwareDetails struct {
 	Host       string
 	Username   string
 	Password   string
+	CACert     string // base64-encoded, optional
 	Datacenter string
 	Cluster    string
 	VMNames    []string
+	Mode       MigrationMode
 }
 
+// getKubeConfig returns the path to a kubeconfig file if one exists under $HOME/.kube/config,
+// and "" otherwise so callers can fall back to in-cluster config.
 func getKubeConfig() string {
 	kubeconfig := filepath.Join(os.Getenv("HOME"), ".kube", "config")
 	if _, err := os.Stat(kubeconfig); os.IsNotExist(err) {
-		log.Fatalf("Kubeconfig not found at %s", kubeconfig)
+		return ""
 	}
 	return kubeconfig
 }
 
-func createSecretForVMware(ctx context.Context, clientset *kubernetes.Clientset, namespace string, name string, username string, password string) error {
+// buildRESTConfig returns a REST config for the target cluster: an explicit kubeconfig file if
+// kubeconfigPath is non-empty, falling back to in-cluster config when running inside a Pod.
+func buildRESTConfig(kubeconfigPath string) (*rest.Config, error) {
+	if kubeconfigPath != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("no kubeconfig found and not running in-cluster: %w", err)
+	}
+	return cfg, nil
+}
+
+// loadVMwareCredentials resolves vCenter credentials, preferring an existing Kubernetes Secret
+// named by the VMWARE_CREDENTIALS_SECRET env var, then falling back to the VMWARE_USERNAME,
+// VMWARE_PASSWORD, and VMWARE_CACERT_FILE env vars. The returned cacert is base64-encoded, as
+// createSecretForVMware expects.
+func loadVMwareCredentials(ctx context.Context, kubeClient *kubernetes.Clientset, namespace string) (username, password, cacertBase64 string, err error) {
+	if secretName := os.Getenv("VMWARE_CREDENTIALS_SECRET"); secretName != "" {
+		secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if err != nil {
+			return "", "", "", fmt.Errorf("reading credentials from secret %s/%s: %w", namespace, secretName, err)
+		}
+		return string(secret.Data["user"]), string(secret.Data["password"]), base64.StdEncoding.EncodeToString(secret.Data["cacert"]), nil
+	}
+
+	username = os.Getenv("VMWARE_USERNAME")
+	password = os.Getenv("VMWARE_PASSWORD")
+	if username == "" || password == "" {
+		return "", "", "", fmt.Errorf("vCenter credentials not found: set VMWARE_USERNAME/VMWARE_PASSWORD or VMWARE_CREDENTIALS_SECRET")
+	}
+
+	if path := os.Getenv("VMWARE_CACERT_FILE"); path != "" {
+		caCert, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", "", fmt.Errorf("reading CA cert file %s: %w", path, err)
+		}
+		cacertBase64 = base64.StdEncoding.EncodeToString(caCert)
+	}
+	return username, password, cacertBase64, nil
+}
+
+// createSecretForVMware stores the vCenter credentials, and optionally its CA bundle, in a
+// Secret that Forklift's vSphere Provider references for authentication. caCertBase64 is the
+// CA bundle as a base64-encoded string (the form it typically arrives in via an env var or an
+// existing Secret's raw Data); it is decoded to PEM before being stored. Pass "" to omit it,
+// which forklift accepts when the vCenter's certificate is already trusted by the cluster.
+func createSecretForVMware(ctx context.Context, clientset *kubernetes.Clientset, namespace, name, username, password, caCertBase64 string) error {
+	stringData := map[string]string{
+		"user":     username,
+		"password": password,
+	}
+	if caCertBase64 != "" {
+		caCert, err := base64.StdEncoding.DecodeString(caCertBase64)
+		if err != nil {
+			return fmt.Errorf("decoding cacert: %w", err)
+		}
+		stringData["cacert"] = string(caCert)
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
-		Type: corev1.SecretTypeOpaque,
-		StringData: map[string]string{
-			"user":     username,
-			"password": password,
-		},
+		Type:       corev1.SecretTypeOpaque,
+		StringData: stringData,
 	}
 	_, err := clientset.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
 	return err
@@ -71,17 +135,12 @@ spec:
 	return []byte(fmt.Sprintf(template, name, namespace, url, secretName, namespace)), nil
 }
 
-func applyYAMLToCluster(yamlContent []byte, k8sClient client.Client) error {
-	obj := map[string]interface{}{}
-	if err := yamlutil.Unmarshal(yamlContent, &obj); err != nil {
-		return err
+func createPlanYAML(planName, sourceProvider, destProvider string, vms []VMRef, networkMap, storageMap, namespace string, mode MigrationMode) ([]byte, error) {
+	var vmEntries strings.Builder
+	for _, vm := range vms {
+		fmt.Fprintf(&vmEntries, "    - id: %s\n", vm.ID)
 	}
-	// this is a simplified version, in production use unstructured.Unstructured
-	fmt.Println("Generated YAML: \n", string(yamlContent))
-	return nil
-}
 
-func createPlanYAML(planName, sourceProvider, destProvider, vmID, networkMap, storageMap, namespace string) ([]byte, error) {
 	template := `
 apiVersion: forklift.konveyor.io/v1beta1
 kind: Plan
@@ -97,10 +156,10 @@ spec:
   map:
     network: %s
     storage: %s
+  warm: %t
   vms:
-    - id: %s
-`
-	return []byte(fmt.Sprintf(template, planName, namespace, sourceProvider, destProvider, networkMap, storageMap, vmID)), nil
+%s`
+	return []byte(fmt.Sprintf(template, planName, namespace, sourceProvider, destProvider, networkMap, storageMap, mode == MigrationModeWarm, vmEntries.String())), nil
 }
 
 func createMigrationYAML(migrationName, planName, namespace string) ([]byte, error) {
@@ -119,11 +178,10 @@ spec:
 
 func main() {
 	ctx := context.TODO()
-	kubeconfigPath := getKubeConfig()
 
-	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	restConfig, err := buildRESTConfig(getKubeConfig())
 	if err != nil {
-		log.Fatalf("Failed to get kubeconfig: %v", err)
+		log.Fatalf("Failed to get cluster config: %v", err)
 	}
 
 	kubeClient, err := kubernetes.NewForConfig(restConfig)
@@ -131,17 +189,24 @@ func main() {
 		log.Fatalf("Failed to create kube client: %v", err)
 	}
 
+	namespace := "forklift"
+	username, password, cacertBase64, err := loadVMwareCredentials(ctx, kubeClient, namespace)
+	if err != nil {
+		log.Fatalf("Failed to load vCenter credentials: %v", err)
+	}
+
 	details := VMwareDetails{
 		Name:     "vmware-demo",
 		Host:     "vcenter.example.com",
-		Username: "administrator@vsphere.local",
-		Password: "your-password",
+		Username: username,
+		Password: password,
+		CACert:   cacertBase64,
 		VMNames:  []string{"TestVM1"},
+		Mode:     MigrationModeCold,
 	}
 
-	namespace := "forklift"
 	secretName := fmt.Sprintf("%s-secret", details.Name)
-	if err := createSecretForVMware(ctx, kubeClient, namespace, secretName, details.Username, details.Password); err != nil {
+	if err := createSecretForVMware(ctx, kubeClient, namespace, secretName, details.Username, details.Password, details.CACert); err != nil {
 		log.Fatalf("Failed to create secret: %v", err)
 	}
 
@@ -150,32 +215,109 @@ func main() {
 		log.Fatalf("Failed to generate provider YAML: %v", err)
 	}
 
-	runtimeClient, err := client.New(restConfig, client.Options{})
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		log.Fatalf("Failed to create dynamic client: %v", err)
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
 	if err != nil {
-		log.Fatalf("Failed to create runtime client: %v", err)
+		log.Fatalf("Failed to create discovery client: %v", err)
 	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
 
-	if err := applyYAMLToCluster(yamlBytes, runtimeClient); err != nil {
+	applyOpts := ApplyOptions{Labels: map[string]string{"app.kubernetes.io/managed-by": "vmware-to-kubevirt-migration"}}
+
+	if _, err := applyYAMLToCluster(ctx, dynamicClient, mapper, yamlBytes, applyOpts); err != nil {
 		log.Fatalf("Failed to apply provider YAML: %v", err)
 	}
 
-	// --- Simulate plan and migration setup ---
-	planName := "migration-plan-demo"
-	networkMap := "default-networkmap"
-	storageMap := "default-storagemap"
-	vmID := "vm-12345" // Ideally discovered from provider inventory
+	inventory, err := NewVMwareInventory(ctx, details)
+	if err != nil {
+		log.Fatalf("Failed to connect to vCenter inventory: %v", err)
+	}
+	defer inventory.Close(ctx)
 
-	planYAML, err := createPlanYAML(planName, details.Name, "kubevirt-provider", vmID, networkMap, storageMap, namespace)
+	vmRecords, err := inventory.ResolveVMNames(ctx, details.VMNames)
+	if err != nil {
+		log.Fatalf("Failed to resolve VMs from vCenter inventory: %v", err)
+	}
+
+	destNetworks, err := DestinationNetworks(ctx, dynamicClient, namespace)
+	if err != nil {
+		log.Fatalf("Failed to list destination networks: %v", err)
+	}
+	destStorageClasses, err := DestinationStorageClasses(ctx, kubeClient)
 	if err != nil {
-		log.Fatalf("Failed to create plan YAML: %v", err)
+		log.Fatalf("Failed to list destination storage classes: %v", err)
+	}
+
+	var sourceNetworks, sourceDatastores []string
+	for _, vm := range vmRecords {
+		sourceNetworks = append(sourceNetworks, vm.Networks...)
+		sourceDatastores = append(sourceDatastores, vm.Datastores...)
 	}
-	_ = applyYAMLToCluster(planYAML, runtimeClient)
 
-	migrationYAML, err := createMigrationYAML("migration-demo", planName, namespace)
+	networkMapName := "migration-networkmap-demo"
+	networkMapYAML, err := createNetworkMapYAML(networkMapName, details.Name, "kubevirt-provider", namespace, BuildNetworkPairings(sourceNetworks, destNetworks))
 	if err != nil {
-		log.Fatalf("Failed to create migration YAML: %v", err)
+		log.Fatalf("Failed to create network map YAML: %v", err)
+	}
+	if _, err := applyYAMLToCluster(ctx, dynamicClient, mapper, networkMapYAML, applyOpts); err != nil {
+		log.Fatalf("Failed to apply network map YAML: %v", err)
 	}
-	_ = applyYAMLToCluster(migrationYAML, runtimeClient)
 
-	fmt.Println("Migration triggered. Monitor status via kubectl.")
+	storageMapName := "migration-storagemap-demo"
+	defaultStorageClass := ""
+	if len(destStorageClasses) > 0 {
+		defaultStorageClass = destStorageClasses[0]
+	}
+	storageMapYAML, err := createStorageMapYAML(storageMapName, details.Name, "kubevirt-provider", namespace, BuildStoragePairings(sourceDatastores, destStorageClasses, defaultStorageClass))
+	if err != nil {
+		log.Fatalf("Failed to create storage map YAML: %v", err)
+	}
+	if _, err := applyYAMLToCluster(ctx, dynamicClient, mapper, storageMapYAML, applyOpts); err != nil {
+		log.Fatalf("Failed to apply storage map YAML: %v", err)
+	}
+
+	vmRefs := make([]VMRef, 0, len(vmRecords))
+	for _, vm := range vmRecords {
+		vmRefs = append(vmRefs, VMRef{ID: vm.ID, Name: vm.Name})
+	}
+
+	orchestratorCfg := OrchestratorConfig{
+		Namespace:      namespace,
+		SourceProvider: details.Name,
+		DestProvider:   "kubevirt-provider",
+		NetworkMap:     networkMapName,
+		StorageMap:     storageMapName,
+		Concurrency:    5,
+		PlanPrefix:     "migration-plan-demo",
+		Mode:           details.Mode,
+	}
+
+	fmt.Println("Migration triggered, waiting for completion...")
+	events := make(chan MigrationEvent)
+	go func() {
+		for evt := range events {
+			fmt.Printf("Migration phase: %s (%d VM(s) reporting)\n", evt.Phase, len(evt.VMs))
+		}
+	}()
+	batch, err := RunBatchMigration(ctx, dynamicClient, mapper, applyOpts, vmRefs, orchestratorCfg, events)
+	close(events)
+	if err != nil {
+		log.Fatalf("Batch migration failed: %v", err)
+	}
+
+	for _, vm := range batch.Succeeded {
+		fmt.Printf("VM %s (%s): succeeded\n", vm.Name, vm.ID)
+	}
+	for _, vm := range batch.Failed {
+		fmt.Printf("VM %s (%s): failed, phase=%s error=%q\n", vm.Name, vm.ID, vm.Phase, vm.Error)
+	}
+
+	if len(batch.Failed) > 0 {
+		log.Fatalf("Migration completed with %d failed VM(s)", len(batch.Failed))
+	}
+	fmt.Println("Migration succeeded.")
 }
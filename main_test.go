@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreatePlanYAMLRendersEveryVM(t *testing.T) {
+	vms := []VMRef{{ID: "vm-101", Name: "web1"}, {ID: "vm-102", Name: "web2"}, {ID: "vm-103", Name: "db1"}}
+
+	yaml, err := createPlanYAML("plan-demo", "vmware-source", "kubevirt-dest", vms, "networkmap-demo", "storagemap-demo", "forklift", MigrationModeCold)
+	if err != nil {
+		t.Fatalf("createPlanYAML returned error: %v", err)
+	}
+
+	got := string(yaml)
+	for _, vm := range vms {
+		if !strings.Contains(got, "- id: "+vm.ID) {
+			t.Errorf("expected plan YAML to include an entry for %s, got:\n%s", vm.ID, got)
+		}
+	}
+	if !strings.Contains(got, "warm: false") {
+		t.Errorf("expected Cold mode to render warm: false, got:\n%s", got)
+	}
+}
+
+func TestCreatePlanYAMLWarmMode(t *testing.T) {
+	yaml, err := createPlanYAML("plan-demo", "vmware-source", "kubevirt-dest", []VMRef{{ID: "vm-101"}}, "networkmap-demo", "storagemap-demo", "forklift", MigrationModeWarm)
+	if err != nil {
+		t.Fatalf("createPlanYAML returned error: %v", err)
+	}
+
+	if !strings.Contains(string(yaml), "warm: true") {
+		t.Errorf("expected Warm mode to render warm: true, got:\n%s", yaml)
+	}
+}
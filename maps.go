@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// networkAttachmentDefinitionGVR is the Multus CRD used to list destination networks.
+var networkAttachmentDefinitionGVR = schema.GroupVersionResource{
+	Group:    "k8s.cni.cncf.io",
+	Version:  "v1",
+	Resource: "network-attachment-definitions",
+}
+
+// NetworkPairing maps one VMware network to a destination: either the pod network, or a
+// Multus NetworkAttachmentDefinition by name/namespace.
+type NetworkPairing struct {
+	Source          string
+	DestinationPod  bool
+	DestinationName string
+	DestinationNS   string
+}
+
+// StoragePairing maps one VMware datastore to a destination StorageClass.
+type StoragePairing struct {
+	Source       string
+	StorageClass string
+}
+
+// DestinationNetworks lists the NetworkAttachmentDefinitions available in namespace on the
+// destination cluster, for matching against discovered VMware networks.
+func DestinationNetworks(ctx context.Context, dynamicClient dynamic.Interface, namespace string) ([]string, error) {
+	list, err := dynamicClient.Resource(networkAttachmentDefinitionGVR).Namespace(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing NetworkAttachmentDefinitions in %s: %w", namespace, err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names, nil
+}
+
+// DestinationStorageClasses lists the StorageClasses available on the destination cluster, for
+// matching against discovered VMware datastores.
+func DestinationStorageClasses(ctx context.Context, kubeClient *kubernetes.Clientset) ([]string, error) {
+	list, err := kubeClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing StorageClasses: %w", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+}
+
+// BuildNetworkPairings pairs each discovered VMware network with a destination
+// NetworkAttachmentDefinition of the same name, falling back to the pod network when no match
+// exists. sourceNetworks is deduplicated first: Forklift rejects a NetworkMap with more than
+// one map entry for the same source network, which a multi-VM batch would otherwise produce
+// whenever VMs share a network.
+func BuildNetworkPairings(sourceNetworks, destinationNADs []string) []NetworkPairing {
+	available := toSet(destinationNADs)
+
+	pairings := make([]NetworkPairing, 0, len(sourceNetworks))
+	for _, network := range dedup(sourceNetworks) {
+		if available[network] {
+			pairings = append(pairings, NetworkPairing{Source: network, DestinationName: network})
+			continue
+		}
+		pairings = append(pairings, NetworkPairing{Source: network, DestinationPod: true})
+	}
+	return pairings
+}
+
+// BuildStoragePairings pairs each discovered VMware datastore with a destination StorageClass
+// of the same name, falling back to defaultStorageClass otherwise.
+// sourceDatastores is deduplicated first, for the same reason as BuildNetworkPairings.
+func BuildStoragePairings(sourceDatastores, destinationStorageClasses []string, defaultStorageClass string) []StoragePairing {
+	available := toSet(destinationStorageClasses)
+
+	pairings := make([]StoragePairing, 0, len(sourceDatastores))
+	for _, datastore := range dedup(sourceDatastores) {
+		if available[datastore] {
+			pairings = append(pairings, StoragePairing{Source: datastore, StorageClass: datastore})
+			continue
+		}
+		pairings = append(pairings, StoragePairing{Source: datastore, StorageClass: defaultStorageClass})
+	}
+	return pairings
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// dedup returns values with duplicates removed, preserving first-seen order.
+func dedup(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		out = append(out, v)
+	}
+	return out
+}
+
+// createNetworkMapYAML renders a Forklift NetworkMap pairing sourceProvider's networks with
+// destinationProvider's, one map entry per pairing.
+func createNetworkMapYAML(name, sourceProvider, destProvider, namespace string, pairings []NetworkPairing) ([]byte, error) {
+	var entries strings.Builder
+	for _, p := range pairings {
+		if p.DestinationPod {
+			fmt.Fprintf(&entries, "  - source:\n      name: %s\n    destination:\n      type: pod\n", p.Source)
+			continue
+		}
+		fmt.Fprintf(&entries, "  - source:\n      name: %s\n    destination:\n      type: multus\n      name: %s\n      namespace: %s\n", p.Source, p.DestinationName, p.DestinationNS)
+	}
+
+	template := `
+apiVersion: forklift.konveyor.io/v1beta1
+kind: NetworkMap
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  provider:
+    source:
+      name: %s
+    destination:
+      name: %s
+  map:
+%s`
+	return []byte(fmt.Sprintf(template, name, namespace, sourceProvider, destProvider, entries.String())), nil
+}
+
+// createStorageMapYAML renders a Forklift StorageMap pairing sourceProvider's datastores with
+// destinationProvider's StorageClasses, one map entry per pairing.
+func createStorageMapYAML(name, sourceProvider, destProvider, namespace string, pairings []StoragePairing) ([]byte, error) {
+	var entries strings.Builder
+	for _, p := range pairings {
+		fmt.Fprintf(&entries, "  - source:\n      name: %s\n    destination:\n      storageClass: %s\n", p.Source, p.StorageClass)
+	}
+
+	template := `
+apiVersion: forklift.konveyor.io/v1beta1
+kind: StorageMap
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  provider:
+    source:
+      name: %s
+    destination:
+      name: %s
+  map:
+%s`
+	return []byte(fmt.Sprintf(template, name, namespace, sourceProvider, destProvider, entries.String())), nil
+}
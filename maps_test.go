@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func TestBuildNetworkPairings(t *testing.T) {
+	sourceNetworks := []string{"vlan-100", "vlan-200", "vlan-100"}
+	destinationNADs := []string{"vlan-100"}
+
+	pairings := BuildNetworkPairings(sourceNetworks, destinationNADs)
+
+	if len(pairings) != 2 {
+		t.Fatalf("expected duplicate source network to be deduplicated, got %d pairings: %+v", len(pairings), pairings)
+	}
+
+	if pairings[0].Source != "vlan-100" || pairings[0].DestinationName != "vlan-100" || pairings[0].DestinationPod {
+		t.Errorf("expected vlan-100 to pair with matching NAD, got %+v", pairings[0])
+	}
+	if pairings[1].Source != "vlan-200" || !pairings[1].DestinationPod {
+		t.Errorf("expected vlan-200 to fall back to the pod network, got %+v", pairings[1])
+	}
+}
+
+func TestBuildStoragePairings(t *testing.T) {
+	sourceDatastores := []string{"datastore1", "datastore2", "datastore1"}
+	destinationStorageClasses := []string{"datastore1"}
+	defaultStorageClass := "standard"
+
+	pairings := BuildStoragePairings(sourceDatastores, destinationStorageClasses, defaultStorageClass)
+
+	if len(pairings) != 2 {
+		t.Fatalf("expected duplicate source datastore to be deduplicated, got %d pairings: %+v", len(pairings), pairings)
+	}
+
+	if pairings[0].Source != "datastore1" || pairings[0].StorageClass != "datastore1" {
+		t.Errorf("expected datastore1 to pair with matching StorageClass, got %+v", pairings[0])
+	}
+	if pairings[1].Source != "datastore2" || pairings[1].StorageClass != defaultStorageClass {
+		t.Errorf("expected datastore2 to fall back to the default StorageClass, got %+v", pairings[1])
+	}
+}
+
+func TestDedup(t *testing.T) {
+	got := dedup([]string{"a", "b", "a", "c", "b"})
+	want := []string{"a", "b", "c"}
+
+	if len(got) != len(want) {
+		t.Fatalf("dedup(...) = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("dedup(...) = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// MigrationMode selects how a Plan migrates its VMs' disks.
+//
+// Cold powers the VM off for the entire transfer: simpler and safer, but downtime equals the
+// full disk copy time. Warm keeps the VM running through repeated CBT-driven precopy
+// iterations that copy only changed blocks since the last snapshot, then powers off for a
+// short final sync at cutover; this trades setup/snapshot overhead for much lower downtime on
+// large VMs. Cold is the default.
+type MigrationMode string
+
+const (
+	MigrationModeCold MigrationMode = "Cold"
+	MigrationModeWarm MigrationMode = "Warm"
+)
+
+// Cutover schedules the final switchover of a warm Migration by patching spec.cutover, after
+// which Forklift stops the VM, copies the remaining CBT delta, and powers it on at the
+// destination. It has no effect on a Cold migration, which cuts over as soon as the initial
+// copy finishes.
+func Cutover(ctx context.Context, dynamicClient dynamic.Interface, migrationName, namespace string, at time.Time) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"cutover":%q}}`, at.UTC().Format(time.RFC3339)))
+	_, err := dynamicClient.Resource(migrationGVR).Namespace(namespace).Patch(ctx, migrationName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("scheduling cutover for migration %s/%s: %w", namespace, migrationName, err)
+	}
+	return nil
+}
+
+// warmPrecopyIterationsForCutover is how many completed precopy iterations runShard waits for,
+// on every VM in a warm Migration, before it schedules cutover.
+const warmPrecopyIterationsForCutover = 1
+
+// driveWarmCutover relays every event received on in to out (which may be nil), and the first
+// time it observes every VM in the Migration having completed at least
+// warmPrecopyIterationsForCutover precopy iterations, schedules cutover for migrationName. It
+// returns once in is closed, which runShard does only after WaitForMigration has returned. The
+// event that ends WaitForMigration is relayed like any other, so if it is the first converged
+// one, cutover is still scheduled on the already-finished Migration.
+func driveWarmCutover(ctx context.Context, dynamicClient dynamic.Interface, migrationName, namespace string, in <-chan MigrationEvent, out chan<- MigrationEvent) {
+	cutoverScheduled := false
+	for evt := range in {
+		if out != nil {
+			out <- evt
+		}
+		if cutoverScheduled || !warmPrecopyConverged(evt, warmPrecopyIterationsForCutover) {
+			continue
+		}
+		cutoverScheduled = true
+		if err := Cutover(ctx, dynamicClient, migrationName, namespace, time.Now()); err != nil {
+			log.Printf("scheduling cutover for migration %s/%s: %v", namespace, migrationName, err)
+		}
+	}
+}
+
+// warmPrecopyConverged reports whether every VM reported in evt has completed at least
+// iterations precopy iterations. An event with no VMs yet is never considered converged.
+func warmPrecopyConverged(evt MigrationEvent, iterations int) bool {
+	if len(evt.VMs) == 0 {
+		return false
+	}
+	for _, vm := range evt.VMs {
+		if len(vm.Precopy) < iterations {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestWarmPrecopyConverged(t *testing.T) {
+	tests := []struct {
+		name string
+		evt  MigrationEvent
+		want bool
+	}{
+		{
+			name: "no VMs reported yet",
+			evt:  MigrationEvent{},
+			want: false,
+		},
+		{
+			name: "one VM short of the required iterations",
+			evt: MigrationEvent{VMs: []VMResult{
+				{Name: "vm1", Precopy: []PrecopyIteration{{Snapshot: "snap-1"}}},
+				{Name: "vm2"},
+			}},
+			want: false,
+		},
+		{
+			name: "every VM has completed the required iterations",
+			evt: MigrationEvent{VMs: []VMResult{
+				{Name: "vm1", Precopy: []PrecopyIteration{{Snapshot: "snap-1"}}},
+				{Name: "vm2", Precopy: []PrecopyIteration{{Snapshot: "snap-1"}}},
+			}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := warmPrecopyConverged(tt.evt, warmPrecopyIterationsForCutover); got != tt.want {
+				t.Errorf("warmPrecopyConverged(%+v, %d) = %v, want %v", tt.evt, warmPrecopyIterationsForCutover, got, tt.want)
+			}
+		})
+	}
+}
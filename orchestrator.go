@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/dynamic"
+)
+
+// defaultMigrationTimeout bounds how long a single shard's Migration is waited on.
+const defaultMigrationTimeout = 2 * time.Hour
+
+// VMRef identifies a single VM to include in a Plan.
+type VMRef struct {
+	ID   string
+	Name string
+}
+
+// OrchestratorConfig controls how a multi-VM migration is sharded into Plans/Migrations.
+type OrchestratorConfig struct {
+	Namespace      string
+	SourceProvider string
+	DestProvider   string
+	NetworkMap     string
+	StorageMap     string
+	Concurrency    int // max VMs in flight per shard; defaults to 5
+	PlanPrefix     string
+	Mode           MigrationMode
+}
+
+// BatchResult aggregates per-VM outcomes across every shard of a batch migration.
+type BatchResult struct {
+	Succeeded []VMResult
+	Failed    []VMResult
+}
+
+// RunBatchMigration shards vms into Plans of at most cfg.Concurrency VMs each and migrates one
+// shard at a time, so no more than cfg.Concurrency VMs are ever in flight together. Per-VM
+// outcomes from every shard's status watcher are aggregated into the returned BatchResult.
+// Every phase/condition transition observed along the way, for any shard, is also sent on
+// events as it happens; events may be nil if the caller only wants the final BatchResult.
+func RunBatchMigration(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, applyOpts ApplyOptions, vms []VMRef, cfg OrchestratorConfig, events chan<- MigrationEvent) (*BatchResult, error) {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 5
+	}
+	result := &BatchResult{}
+	for shardIndex, shard := range shardVMRefs(vms, cfg.Concurrency) {
+		report, err := runShard(ctx, dynamicClient, mapper, applyOpts, shard, cfg, shardIndex, events)
+		if err != nil {
+			return result, fmt.Errorf("shard %d: %w", shardIndex, err)
+		}
+		for _, vm := range report.VMs {
+			if vm.Error != "" || vm.Phase != "Succeeded" {
+				result.Failed = append(result.Failed, vm)
+			} else {
+				result.Succeeded = append(result.Succeeded, vm)
+			}
+		}
+	}
+	return result, nil
+}
+
+// RetryFailed re-runs only the VMs in failed, under new Plan/Migration names, without rerunning
+// VMs that already succeeded in an earlier call to RunBatchMigration.
+func RetryFailed(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, applyOpts ApplyOptions, failed []VMResult, cfg OrchestratorConfig, events chan<- MigrationEvent) (*BatchResult, error) {
+	vms := make([]VMRef, 0, len(failed))
+	for _, vm := range failed {
+		vms = append(vms, VMRef{ID: vm.ID, Name: vm.Name})
+	}
+	cfg.PlanPrefix = fmt.Sprintf("%s-retry-%d", cfg.PlanPrefix, time.Now().Unix())
+	return RunBatchMigration(ctx, dynamicClient, mapper, applyOpts, vms, cfg, events)
+}
+
+// shardVMRefs splits vms into consecutive chunks of at most size VMs.
+func shardVMRefs(vms []VMRef, size int) [][]VMRef {
+	var shards [][]VMRef
+	for i := 0; i < len(vms); i += size {
+		end := i + size
+		if end > len(vms) {
+			end = len(vms)
+		}
+		shards = append(shards, vms[i:end])
+	}
+	return shards
+}
+
+// runShard applies a Plan+Migration covering shard and blocks until that Migration finishes,
+// forwarding every phase/condition transition observed to events (which may be nil). For a warm
+// Migration, it also schedules cutover itself once every VM's precopy has converged, since
+// Forklift never cuts a warm Migration over on its own.
+func runShard(ctx context.Context, dynamicClient dynamic.Interface, mapper meta.RESTMapper, applyOpts ApplyOptions, shard []VMRef, cfg OrchestratorConfig, shardIndex int, events chan<- MigrationEvent) (*MigrationReport, error) {
+	planName := fmt.Sprintf("%s-plan-%d", cfg.PlanPrefix, shardIndex)
+	migrationName := fmt.Sprintf("%s-migration-%d", cfg.PlanPrefix, shardIndex)
+
+	planYAML, err := createPlanYAML(planName, cfg.SourceProvider, cfg.DestProvider, shard, cfg.NetworkMap, cfg.StorageMap, cfg.Namespace, cfg.Mode)
+	if err != nil {
+		return nil, fmt.Errorf("building plan YAML: %w", err)
+	}
+	if _, err := applyYAMLToCluster(ctx, dynamicClient, mapper, planYAML, applyOpts); err != nil {
+		return nil, fmt.Errorf("applying plan %s: %w", planName, err)
+	}
+
+	migrationYAML, err := createMigrationYAML(migrationName, planName, cfg.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("building migration YAML: %w", err)
+	}
+	if _, err := applyYAMLToCluster(ctx, dynamicClient, mapper, migrationYAML, applyOpts); err != nil {
+		return nil, fmt.Errorf("applying migration %s: %w", migrationName, err)
+	}
+
+	if cfg.Mode != MigrationModeWarm {
+		return WaitForMigration(ctx, dynamicClient, migrationName, cfg.Namespace, defaultMigrationTimeout, events)
+	}
+
+	relay := make(chan MigrationEvent)
+	relayDone := make(chan struct{})
+	go func() {
+		driveWarmCutover(ctx, dynamicClient, migrationName, cfg.Namespace, relay, events)
+		close(relayDone)
+	}()
+	report, err := WaitForMigration(ctx, dynamicClient, migrationName, cfg.Namespace, defaultMigrationTimeout, relay)
+	close(relay)
+	<-relayDone
+	return report, err
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShardVMRefs(t *testing.T) {
+	vms := []VMRef{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+
+	tests := []struct {
+		name string
+		vms  []VMRef
+		size int
+		want [][]VMRef
+	}{
+		{
+			name: "evenly divides",
+			vms:  vms[:4],
+			size: 2,
+			want: [][]VMRef{{vms[0], vms[1]}, {vms[2], vms[3]}},
+		},
+		{
+			name: "remainder shard is partial",
+			vms:  vms,
+			size: 2,
+			want: [][]VMRef{{vms[0], vms[1]}, {vms[2], vms[3]}, {vms[4]}},
+		},
+		{
+			name: "size larger than input yields one shard",
+			vms:  vms[:2],
+			size: 5,
+			want: [][]VMRef{{vms[0], vms[1]}},
+		},
+		{
+			name: "empty input yields no shards",
+			vms:  nil,
+			size: 5,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shardVMRefs(tt.vms, tt.size)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shardVMRefs(%v, %d) = %v, want %v", tt.vms, tt.size, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBatchMigrationEmptyVMsIsNoOp(t *testing.T) {
+	cfg := OrchestratorConfig{Mode: MigrationModeWarm}
+	result, err := RunBatchMigration(nil, nil, nil, ApplyOptions{}, nil, cfg, nil)
+	if err != nil {
+		t.Fatalf("RunBatchMigration with no VMs returned error: %v", err)
+	}
+	if len(result.Succeeded) != 0 || len(result.Failed) != 0 {
+		t.Errorf("expected an empty BatchResult, got %+v", result)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+)
+
+// migrationGVR is the Forklift Migration resource watched by WaitForMigration.
+var migrationGVR = schema.GroupVersionResource{
+	Group:    "forklift.konveyor.io",
+	Version:  "v1beta1",
+	Resource: "migrations",
+}
+
+// VMResult is the terminal outcome of a single VM within a Migration.
+type VMResult struct {
+	ID       string
+	Name     string
+	Phase    string
+	Pipeline []string
+	Error    string
+	Precopy  []PrecopyIteration
+}
+
+// PrecopyIteration is one CBT-driven incremental disk sync performed ahead of cutover during a
+// warm migration.
+type PrecopyIteration struct {
+	Snapshot string
+	Start    string
+	End      string
+}
+
+// MigrationEvent is a single phase/condition transition observed while watching a Migration.
+type MigrationEvent struct {
+	Phase string
+	VMs   []VMResult
+}
+
+// MigrationReport is the final outcome returned once a Migration reaches a terminal condition.
+type MigrationReport struct {
+	Succeeded bool
+	VMs       []VMResult
+}
+
+// WaitForMigration blocks until the named Forklift Migration reaches a terminal condition
+// (Succeeded=True or Failed=True), ctx is cancelled, or timeout elapses. Every phase/condition
+// transition is sent on events as it is observed, so callers can surface live progress; events
+// may be nil if the caller only wants the final report. An initial List seeds resourceVersion
+// and is itself checked for a terminal condition, so a Migration that is already done (or
+// finishes in the gap between applying it and starting the watch) is still detected instead of
+// blocking until timeout. The watch is restarted transparently (resuming from the last observed
+// resourceVersion) if it is interrupted by a 410 Gone/EOF.
+func WaitForMigration(ctx context.Context, dynamicClient dynamic.Interface, name, namespace string, timeout time.Duration, events chan<- MigrationEvent) (*MigrationReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resourceVersion, current, err := listMigration(ctx, dynamicClient, name, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("listing migration %s/%s: %w", namespace, name, err)
+	}
+	if current != nil {
+		phase, vms := extractMigrationStatus(current)
+		if events != nil {
+			events <- MigrationEvent{Phase: phase, VMs: vms}
+		}
+		if succeeded, failed := migrationConditionStatus(current); succeeded || failed {
+			return &MigrationReport{Succeeded: succeeded, VMs: vms}, nil
+		}
+	}
+
+	for {
+		w, err := startMigrationWatch(ctx, dynamicClient, name, namespace, resourceVersion)
+		if err != nil {
+			return nil, fmt.Errorf("watching migration %s/%s: %w", namespace, name, err)
+		}
+
+		report, lastResourceVersion, err := consumeMigrationWatch(ctx, w, events)
+		w.Stop()
+		if err == nil {
+			return report, nil
+		}
+		if errors.Is(err, io.EOF) || apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+			resourceVersion = lastResourceVersion
+			continue
+		}
+		return nil, fmt.Errorf("watching migration %s/%s: %w", namespace, name, err)
+	}
+}
+
+// listMigration lists the named Migration (there is at most one) and returns the list's
+// resourceVersion to resume a subsequent watch from, along with the Migration itself if it
+// already exists.
+func listMigration(ctx context.Context, dynamicClient dynamic.Interface, name, namespace string) (string, *unstructured.Unstructured, error) {
+	list, err := dynamicClient.Resource(migrationGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String(),
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	if len(list.Items) == 0 {
+		return list.GetResourceVersion(), nil, nil
+	}
+	return list.GetResourceVersion(), &list.Items[0], nil
+}
+
+// startMigrationWatch opens a watch on the named Migration, optionally resuming from
+// resourceVersion after a restart.
+func startMigrationWatch(ctx context.Context, dynamicClient dynamic.Interface, name, namespace, resourceVersion string) (watch.Interface, error) {
+	return dynamicClient.Resource(migrationGVR).Namespace(namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", name).String(),
+		ResourceVersion: resourceVersion,
+	})
+}
+
+// consumeMigrationWatch drains watch events, emitting a MigrationEvent on events for every
+// update, and returns the terminal MigrationReport once the Migration reports Succeeded or
+// Failed. It returns io.EOF if the watch channel closes before a terminal condition is seen.
+func consumeMigrationWatch(ctx context.Context, w watch.Interface, events chan<- MigrationEvent) (*MigrationReport, string, error) {
+	lastResourceVersion := ""
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, lastResourceVersion, ctx.Err()
+		case evt, ok := <-w.ResultChan():
+			if !ok {
+				return nil, lastResourceVersion, io.EOF
+			}
+			if evt.Type == watch.Error {
+				return nil, lastResourceVersion, fmt.Errorf("watch error event: %w", apierrors.FromObject(evt.Object))
+			}
+
+			obj, ok := evt.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			lastResourceVersion = obj.GetResourceVersion()
+
+			phase, vms := extractMigrationStatus(obj)
+			if events != nil {
+				events <- MigrationEvent{Phase: phase, VMs: vms}
+			}
+
+			if succeeded, failed := migrationConditionStatus(obj); succeeded || failed {
+				return &MigrationReport{Succeeded: succeeded, VMs: vms}, lastResourceVersion, nil
+			}
+		}
+	}
+}
+
+// extractMigrationStatus pulls status.vms[] out of a Migration object, along with a phase: that
+// of the last VM listed, or "Unknown" when no VM is listed yet.
+func extractMigrationStatus(obj *unstructured.Unstructured) (string, []VMResult) {
+	vmsRaw, _, _ := unstructured.NestedSlice(obj.Object, "status", "vms")
+	vms := make([]VMResult, 0, len(vmsRaw))
+	phase := "Unknown"
+	for _, raw := range vmsRaw {
+		vm, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		result := VMResult{
+			ID:    stringField(vm, "id"),
+			Name:  stringField(vm, "name"),
+			Phase: stringField(vm, "phase"),
+			Error: stringField(vm, "error"),
+		}
+		if pipeline, ok := vm["pipeline"].([]interface{}); ok {
+			for _, step := range pipeline {
+				if s, ok := step.(map[string]interface{}); ok {
+					result.Pipeline = append(result.Pipeline, stringField(s, "phase"))
+				}
+			}
+		}
+		if warm, ok := vm["warm"].(map[string]interface{}); ok {
+			if precopies, ok := warm["precopies"].([]interface{}); ok {
+				for _, raw := range precopies {
+					if p, ok := raw.(map[string]interface{}); ok {
+						result.Precopy = append(result.Precopy, PrecopyIteration{
+							Snapshot: stringField(p, "snapshot"),
+							Start:    stringField(p, "start"),
+							End:      stringField(p, "end"),
+						})
+					}
+				}
+			}
+		}
+		vms = append(vms, result)
+		phase = result.Phase
+	}
+	return phase, vms
+}
+
+// migrationConditionStatus reports whether status.conditions contains a True Succeeded or
+// Failed condition.
+func migrationConditionStatus(obj *unstructured.Unstructured) (succeeded, failed bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, raw := range conditions {
+		cond, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if stringField(cond, "status") != string(metav1.ConditionTrue) {
+			continue
+		}
+		switch stringField(cond, "type") {
+		case "Succeeded":
+			succeeded = true
+		case "Failed":
+			failed = true
+		}
+	}
+	return succeeded, failed
+}
+
+func stringField(m map[string]interface{}, key string) string {
+	v, _ := m[key].(string)
+	return v
+}